@@ -0,0 +1,234 @@
+package logformat
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+// DropPolicy controls what AsyncWriter does when its buffer is full.
+type DropPolicy int
+
+const (
+	// DropOldest discards the oldest buffered record to make room for
+	// the new one.
+	DropOldest DropPolicy = iota
+	// DropNewest discards the incoming record, leaving the buffer as-is.
+	DropNewest
+	// Block makes the caller wait until buffer space is available.
+	Block
+)
+
+// ErrAsyncWriterClosed is returned by Write once Flush has been called,
+// whether or not that Flush has finished.
+var ErrAsyncWriterClosed = errors.New("logformat: async writer is closed")
+
+// AsyncWriter wraps an io.Writer so that Write enqueues onto a bounded
+// channel instead of blocking the caller on a slow sink (a file on NFS, a
+// remote syslog endpoint, etc). A single background goroutine drains the
+// channel into the underlying writer.
+type AsyncWriter struct {
+	w      io.Writer
+	ch     chan []byte
+	policy DropPolicy
+
+	dropped  uint64
+	enqueued uint64
+
+	// mu guards closing, inFlight and drainedSignaled. It is only ever
+	// held for the short bookkeeping steps below, never across a channel
+	// send — a Block-policy Write can sit in its channel send for as long
+	// as the underlying writer is stuck, and Flush must stay cancelable
+	// via ctx through all of that, so nothing here may take an unbounded
+	// lock.
+	mu              sync.Mutex
+	closing         bool // set once Flush begins; Write checks this to stop accepting
+	inFlight        int  // number of Writes that passed the closing check and haven't finished their send yet
+	drainedSignaled bool
+
+	closeOnce        sync.Once
+	signalClosedOnce sync.Once
+	// drained is closed once closing is set and inFlight has reached
+	// zero, i.e. every already-accepted Write has finished sending to ch.
+	drained chan struct{}
+	// closed tells run() it's safe to do its final drain and exit; it is
+	// only closed once drained has fired, so run() never races a Write
+	// that's still in the middle of sending.
+	closed chan struct{}
+	done   chan struct{}
+}
+
+// NewAsyncWriter starts the background drain goroutine and returns an
+// AsyncWriter ready to use. capacity is the number of buffered records
+// before policy kicks in.
+func NewAsyncWriter(w io.Writer, capacity int, policy DropPolicy) *AsyncWriter {
+	a := &AsyncWriter{
+		w:       w,
+		ch:      make(chan []byte, capacity),
+		policy:  policy,
+		drained: make(chan struct{}),
+		closed:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go a.run()
+	return a
+}
+
+// NewVaultLoggerAsync creates a Vault logger whose writer is wrapped in an
+// AsyncWriter, so high-volume request logging doesn't block the caller on
+// the underlying sink. The returned AsyncWriter exposes Flush for graceful
+// shutdown and Dropped/Enqueued counters.
+func NewVaultLoggerAsync(w io.Writer, level int, capacity int, policy DropPolicy) (log.Logger, *AsyncWriter) {
+	async := NewAsyncWriter(w, capacity, policy)
+	return NewVaultLoggerWithWriter(async, level), async
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	for {
+		select {
+		case b := <-a.ch:
+			a.w.Write(b)
+		case <-a.closed:
+			// Drain whatever is left before exiting.
+			for {
+				select {
+				case b := <-a.ch:
+					a.w.Write(b)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Write enqueues p for asynchronous delivery to the underlying writer. p
+// is copied, so the caller may reuse its backing array immediately.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	if a.closing {
+		a.mu.Unlock()
+		return 0, ErrAsyncWriterClosed
+	}
+	a.inFlight++
+	a.mu.Unlock()
+	defer a.finishWrite()
+
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	atomic.AddUint64(&a.enqueued, 1)
+
+	switch a.policy {
+	case Block:
+		select {
+		case a.ch <- buf:
+		case <-a.closed:
+			return 0, ErrAsyncWriterClosed
+		}
+	case DropNewest:
+		select {
+		case a.ch <- buf:
+		default:
+			atomic.AddUint64(&a.dropped, 1)
+		}
+	default: // DropOldest
+		// Busy-spins against run()'s drain loop rather than blocking: the
+		// tradeoff is CPU burned under contention in exchange for never
+		// stalling the caller, which is the whole point of this policy.
+		// Under sustained pressure this can spin for a while before
+		// winning a slot; Block is the right policy if that cost matters
+		// more than staying off the hot path.
+		for {
+			select {
+			case a.ch <- buf:
+				return len(p), nil
+			default:
+			}
+			select {
+			case <-a.ch:
+				atomic.AddUint64(&a.dropped, 1)
+			default:
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// finishWrite decrements the in-flight count and, if Flush is already
+// waiting for it, signals drained the moment no Write is left sending.
+func (a *AsyncWriter) finishWrite() {
+	a.mu.Lock()
+	a.inFlight--
+	if a.closing && a.inFlight == 0 && !a.drainedSignaled {
+		a.drainedSignaled = true
+		close(a.drained)
+	}
+	a.mu.Unlock()
+}
+
+// Flush stops accepting new records, drains whatever is already buffered
+// to the underlying writer, and returns once draining is complete or ctx
+// is done, whichever comes first. Once Flush returns nil, every record
+// that a prior Write placed in the buffer, and that was not discarded
+// since under DropOldest, is guaranteed to have reached the underlying
+// writer. Records discarded under DropNewest or DropOldest never do, even
+// though their Write returned a nil error.
+//
+// Every wait here is against a channel select, never a lock: if the
+// underlying writer is stuck (a hung NFS mount, a dead syslog connection)
+// a Block-policy Write can be stuck sending to ch indefinitely, and ctx
+// must still be able to cut Flush short in that case rather than hanging
+// with it.
+func (a *AsyncWriter) Flush(ctx context.Context) error {
+	a.closeOnce.Do(func() {
+		a.mu.Lock()
+		a.closing = true
+		if a.inFlight == 0 && !a.drainedSignaled {
+			a.drainedSignaled = true
+			close(a.drained)
+		}
+		a.mu.Unlock()
+	})
+
+	select {
+	case <-a.drained:
+		// No Write is still sending to ch, so it's safe to tell run() to
+		// do its final drain and exit.
+		a.signalClosedOnce.Do(func() { close(a.closed) })
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Dropped returns the number of records discarded under DropOldest or
+// DropNewest because the buffer was full.
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+// Enqueued returns the number of records accepted by Write, including
+// ones later dropped.
+func (a *AsyncWriter) Enqueued() uint64 {
+	return atomic.LoadUint64(&a.enqueued)
+}
+
+// Publish exposes Dropped/Enqueued as expvar counters under
+// "<name>.dropped" and "<name>.enqueued", for operators scraping an
+// existing /debug/vars endpoint rather than polling the methods directly.
+func (a *AsyncWriter) Publish(name string) {
+	expvar.Publish(name+".dropped", expvar.Func(func() interface{} { return a.Dropped() }))
+	expvar.Publish(name+".enqueued", expvar.Func(func() interface{} { return a.Enqueued() }))
+}
@@ -0,0 +1,249 @@
+package logformat
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blockingWriter lets a test deterministically observe when run()'s single
+// drain goroutine has dequeued a record and is in the middle of writing
+// it, by blocking in Write until the test closes release.
+type blockingWriter struct {
+	entered chan struct{}
+	release chan struct{}
+
+	mu     sync.Mutex
+	writes []string
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		entered: make(chan struct{}, 64),
+		release: make(chan struct{}),
+	}
+}
+
+func (b *blockingWriter) Write(p []byte) (int, error) {
+	b.entered <- struct{}{}
+	<-b.release
+	b.mu.Lock()
+	b.writes = append(b.writes, string(p))
+	b.mu.Unlock()
+	return len(p), nil
+}
+
+func (b *blockingWriter) snapshot() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	out := make([]string, len(b.writes))
+	copy(out, b.writes)
+	return out
+}
+
+func TestAsyncWriterDropNewest(t *testing.T) {
+	bw := newBlockingWriter()
+	aw := NewAsyncWriter(bw, 1, DropNewest)
+
+	if _, err := aw.Write([]byte("a")); err != nil {
+		t.Fatalf("Write(a): %v", err)
+	}
+	<-bw.entered // run() has dequeued "a" and is blocked writing it
+
+	if _, err := aw.Write([]byte("b")); err != nil {
+		t.Fatalf("Write(b): %v", err)
+	}
+	if _, err := aw.Write([]byte("c")); err != nil {
+		t.Fatalf("Write(c) should be accepted by the caller even though dropped: %v", err)
+	}
+
+	if got := aw.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+	if got := aw.Enqueued(); got != 3 {
+		t.Errorf("Enqueued() = %d, want 3", got)
+	}
+
+	close(bw.release)
+	flush(t, aw)
+
+	if got := bw.snapshot(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("writes = %v, want [a b] (c should have been dropped, not b)", got)
+	}
+}
+
+func TestAsyncWriterDropOldest(t *testing.T) {
+	bw := newBlockingWriter()
+	aw := NewAsyncWriter(bw, 1, DropOldest)
+
+	if _, err := aw.Write([]byte("a")); err != nil {
+		t.Fatalf("Write(a): %v", err)
+	}
+	<-bw.entered // run() has dequeued "a" and is blocked writing it
+
+	if _, err := aw.Write([]byte("b")); err != nil {
+		t.Fatalf("Write(b): %v", err)
+	}
+	if _, err := aw.Write([]byte("c")); err != nil {
+		t.Fatalf("Write(c): %v", err)
+	}
+
+	if got := aw.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+	if got := aw.Enqueued(); got != 3 {
+		t.Errorf("Enqueued() = %d, want 3", got)
+	}
+
+	close(bw.release)
+	flush(t, aw)
+
+	if got := bw.snapshot(); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("writes = %v, want [a c] (b should have been the one dropped)", got)
+	}
+}
+
+func TestAsyncWriterBlockPolicyWaitsForSpace(t *testing.T) {
+	bw := newBlockingWriter()
+	aw := NewAsyncWriter(bw, 1, Block)
+
+	if _, err := aw.Write([]byte("a")); err != nil {
+		t.Fatalf("Write(a): %v", err)
+	}
+	<-bw.entered // run() has dequeued "a" and is blocked writing it
+
+	if _, err := aw.Write([]byte("b")); err != nil {
+		t.Fatalf("Write(b): %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		if _, err := aw.Write([]byte("c")); err != nil {
+			t.Errorf("Write(c): %v", err)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Block policy Write returned before the buffer had space")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(bw.release)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Block policy Write never unblocked after space freed")
+	}
+
+	flush(t, aw)
+}
+
+// TestAsyncWriterFlushRespectsContextWhenWriterHangs is a regression test
+// for a Flush that hung forever on a stuck underlying writer instead of
+// honoring ctx: with the drain goroutine wedged inside a writer that never
+// returns and another record waiting in the buffer behind it, Flush must
+// still come back as soon as ctx expires.
+func TestAsyncWriterFlushRespectsContextWhenWriterHangs(t *testing.T) {
+	hang := make(chan struct{})
+	w := writerFunc(func(p []byte) (int, error) {
+		<-hang // never returns until the test says so
+		return len(p), nil
+	})
+
+	aw := NewAsyncWriter(w, 1, Block)
+
+	// The first Write is picked up by run() and hangs inside w.Write; the
+	// second one lands in the single buffer slot behind it (waiting in its
+	// send until run() has taken "a"), so it can never be drained.
+	if _, err := aw.Write([]byte("a")); err != nil {
+		t.Fatalf("Write(a): %v", err)
+	}
+
+	go aw.Write([]byte("b")) // its record is never delivered; leaked on purpose for this test
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := aw.Flush(ctx)
+	elapsed := time.Since(start)
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("Flush returned %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed > time.Second {
+		t.Fatalf("Flush took %v to honor a 200ms deadline against a hung writer", elapsed)
+	}
+
+	close(hang) // let the leaked goroutines finish so the test can exit cleanly
+}
+
+// TestAsyncWriterFlushDoesNotDropInFlightWrites is a regression test for
+// the shutdown race where Flush could return success while a concurrent
+// Write had already been accepted (enqueued incremented, no error
+// returned) but raced run()'s final drain and was never delivered. Every
+// Write that returns a nil error must have its record reach the
+// underlying writer by the time Flush returns.
+func TestAsyncWriterFlushDoesNotDropInFlightWrites(t *testing.T) {
+	var mu sync.Mutex
+	delivered := 0
+	w := writerFunc(func(p []byte) (int, error) {
+		mu.Lock()
+		delivered++
+		mu.Unlock()
+		return len(p), nil
+	})
+
+	const n = 200
+	aw := NewAsyncWriter(w, 4, Block)
+
+	var wg sync.WaitGroup
+	var accepted, rejected int64
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := aw.Write([]byte("x")); err != nil {
+				atomic.AddInt64(&rejected, 1)
+			} else {
+				atomic.AddInt64(&accepted, 1)
+			}
+		}()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := aw.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	got := delivered
+	mu.Unlock()
+
+	if int64(got) != accepted {
+		t.Fatalf("delivered=%d but accepted=%d: a Write returned success whose record never reached the writer", got, accepted)
+	}
+	if accepted+rejected != n {
+		t.Fatalf("accepted+rejected = %d, want %d", accepted+rejected, n)
+	}
+}
+
+type writerFunc func([]byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+func flush(t *testing.T, aw *AsyncWriter) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := aw.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+}
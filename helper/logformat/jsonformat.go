@@ -0,0 +1,140 @@
+package logformat
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+// jsonFormatter renders a LogRecord as a single line of JSON, matching the
+// shape Vault has always emitted: "@message", "@timestamp", "@level",
+// "@module", plus the flattened key/value args.
+type jsonFormatter struct{}
+
+func (j *jsonFormatter) Format(r *LogRecord) ([]byte, error) {
+	vals := map[string]interface{}{
+		"@message":   r.Message,
+		"@timestamp": r.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"),
+		"@level":     levelStringFor(r.Level),
+	}
+
+	if r.Module != "" {
+		vals["@module"] = r.Module
+	}
+
+	args := r.Args
+	if len(args) > 0 {
+		if len(args)%2 != 0 {
+			args = append(args, "[unknown!]")
+		}
+
+		var warn bool
+		for i := 0; i < len(args); i = i + 2 {
+			key, ok := args[i].(string)
+			if !ok {
+				// As this is the logging function not much we can do here
+				// without injecting into logs...
+				continue
+			}
+			val, valWarn := sanitizeJSONValue(args[i+1])
+			vals[key] = val
+			warn = warn || valWarn
+		}
+		if warn {
+			if _, exists := vals["@warn"]; !exists {
+				vals["@warn"] = "logging contained values that don't serialize to json"
+			}
+		}
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	enc := json.NewEncoder(buf)
+	if err := enc.Encode(vals); err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, buf.Len())
+	copy(out, buf.Bytes())
+	return out, nil
+}
+
+// sanitizeJSONValue coerces v into something encoding/json is guaranteed
+// to marshal, so that one bad value (a channel, a func, a cyclic struct,
+// an error without MarshalJSON) can't cause the whole record to be
+// silently dropped by enc.Encode. It reports true if v had to be coerced
+// via fmt.Sprintf, so the caller can surface an "@warn" field.
+func sanitizeJSONValue(v interface{}) (interface{}, bool) {
+	if v == nil {
+		return nil, false
+	}
+
+	// []byte (or any named byte slice type) is encoded as base64 rather
+	// than an array of small integers.
+	if b, ok := v.([]byte); ok {
+		return base64.StdEncoding.EncodeToString(b), false
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+		b := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(b), rv)
+		return base64.StdEncoding.EncodeToString(b), false
+	}
+
+	// A typed-nil pointer (e.g. a nil *MyErr stored in an error or
+	// json.Marshaler interface) is non-nil as an interface value but
+	// will panic if we call a method that dereferences the receiver.
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return nil, false
+	}
+
+	if _, ok := v.(json.Marshaler); ok {
+		if b, err := json.Marshal(v); err == nil {
+			var out interface{}
+			if jsonErr := json.Unmarshal(b, &out); jsonErr == nil {
+				return out, false
+			}
+			return v, false
+		}
+	}
+
+	if tm, ok := v.(encoding.TextMarshaler); ok {
+		if text, err := tm.MarshalText(); err == nil {
+			return string(text), false
+		}
+	}
+
+	if errVal, ok := v.(error); ok {
+		return errVal.Error(), false
+	}
+
+	if _, err := json.Marshal(v); err != nil {
+		return fmt.Sprintf("%v", v), true
+	}
+	return v, false
+}
+
+// levelStringFor returns the lowercase level name used by the JSON and
+// logfmt styles.
+func levelStringFor(level int) string {
+	switch level {
+	case log.LevelCritical:
+		return "critical"
+	case log.LevelError:
+		return "error"
+	case log.LevelWarn:
+		return "warn"
+	case log.LevelInfo:
+		return "info"
+	case log.LevelDebug:
+		return "debug"
+	case log.LevelTrace:
+		return "trace"
+	default:
+		return "all"
+	}
+}
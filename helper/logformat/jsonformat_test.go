@@ -0,0 +1,106 @@
+package logformat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+type textMarshalerStub struct{ s string }
+
+func (t textMarshalerStub) MarshalText() ([]byte, error) { return []byte(t.s), nil }
+
+type jsonMarshalerStub struct{ n int }
+
+func (j jsonMarshalerStub) MarshalJSON() ([]byte, error) { return []byte(fmt.Sprintf("%d", j.n)), nil }
+
+type stubError struct{ msg string }
+
+func (e *stubError) Error() string { return e.msg }
+
+type namedByteSlice []byte
+
+func TestSanitizeJSONValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       interface{}
+		want     interface{}
+		wantWarn bool
+	}{
+		{"nil", nil, nil, false},
+		{"typed nil pointer", (*stubError)(nil), nil, false},
+		{"byte slice becomes base64", []byte("hi"), base64.StdEncoding.EncodeToString([]byte("hi")), false},
+		{"named byte slice type also becomes base64", namedByteSlice("hey"), base64.StdEncoding.EncodeToString([]byte("hey")), false},
+		{"error uses Error()", &stubError{msg: "boom"}, "boom", false},
+		{"TextMarshaler", textMarshalerStub{s: "marshaled"}, "marshaled", false},
+		{"json.Marshaler", jsonMarshalerStub{n: 7}, float64(7), false},
+		{"plain value passes through", "hello", "hello", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, warn := sanitizeJSONValue(tt.in)
+			if warn != tt.wantWarn {
+				t.Errorf("warn = %v, want %v", warn, tt.wantWarn)
+			}
+			if got != tt.want {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestSanitizeJSONValueNonSerializable covers values that json.Marshal
+// itself rejects (channels, funcs): they must fall back to fmt.Sprintf
+// rather than causing the caller's enc.Encode to drop the whole record.
+func TestSanitizeJSONValueNonSerializable(t *testing.T) {
+	for name, v := range map[string]interface{}{
+		"channel": make(chan int),
+		"func":    func() {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, warn := sanitizeJSONValue(v)
+			if !warn {
+				t.Fatalf("warn = false, want true for %v", v)
+			}
+			s, ok := got.(string)
+			if !ok || s == "" {
+				t.Fatalf("got = %#v, want a non-empty string fallback", got)
+			}
+		})
+	}
+}
+
+// TestJSONFormatterFormatSurvivesBadValues is a Format-level regression
+// test for the bug this sanitizer fixes: a single non-serializable value
+// used to make enc.Encode silently drop the entire record.
+func TestJSONFormatterFormatSurvivesBadValues(t *testing.T) {
+	jf := &jsonFormatter{}
+	rec := &LogRecord{
+		Timestamp: time.Now(),
+		Level:     log.LevelInfo,
+		Message:   "test",
+		Args:      []interface{}{"ch", make(chan int), "ok", "fine"},
+	}
+
+	b, err := jf.Format(rec)
+	if err != nil {
+		t.Fatalf("Format returned error: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("Format produced invalid JSON: %v (%s)", err, b)
+	}
+
+	if decoded["@warn"] == nil {
+		t.Errorf("expected @warn to be set when a value can't serialize, got %v", decoded)
+	}
+	if decoded["ok"] != "fine" {
+		t.Errorf("expected ok=fine to pass through untouched, got %v", decoded["ok"])
+	}
+}
@@ -0,0 +1,59 @@
+package logformat
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// logfmtFormatter renders a LogRecord in the "key=value" style popularized
+// by Heroku's logfmt, one record per line: ts=... level=... module=...
+// msg="..." plus the flattened key/value args.
+type logfmtFormatter struct{}
+
+func (l *logfmtFormatter) Format(r *LogRecord) ([]byte, error) {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	writePair(buf, "ts", r.Timestamp.Format("2006-01-02T15:04:05.000000Z07:00"))
+	buf.WriteString(" ")
+	writePair(buf, "level", levelStringFor(r.Level))
+
+	if r.Module != "" {
+		buf.WriteString(" ")
+		writePair(buf, "module", r.Module)
+	}
+
+	buf.WriteString(" ")
+	writePair(buf, "msg", r.Message)
+
+	args := r.Args
+	if len(args) > 0 {
+		if len(args)%2 != 0 {
+			args = append(args, "[unknown!]")
+		}
+
+		for i := 0; i < len(args); i = i + 2 {
+			buf.WriteString(" ")
+			writePair(buf, fmt.Sprintf("%v", args[i]), args[i+1])
+		}
+	}
+
+	buf.WriteString("\n")
+
+	out := make([]byte, buf.Len())
+	copy(out, buf.Bytes())
+	return out, nil
+}
+
+// writePair writes key=value to buf, quoting the value if it contains
+// whitespace, an equals sign or a double quote.
+func writePair(buf *bytes.Buffer, key string, value interface{}) {
+	str := fmt.Sprintf("%v", value)
+	if strings.ContainsAny(str, " \t\r\n\v\f=\"") {
+		str = fmt.Sprintf("%q", str)
+	}
+	buf.WriteString(key)
+	buf.WriteString("=")
+	buf.WriteString(str)
+}
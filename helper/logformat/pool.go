@@ -0,0 +1,23 @@
+package logformat
+
+import (
+	"bytes"
+	"sync"
+)
+
+// bufferPool holds per-goroutine scratch buffers for LogFormatter
+// implementations, so repeated Format calls don't pay for a fresh
+// allocation (and its backing array growth) on every record.
+var bufferPool = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	bufferPool.Put(buf)
+}
@@ -0,0 +1,97 @@
+package logformat
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+// LogRecord is the intermediate representation of a single log call,
+// independent of how it will ultimately be rendered. LogFormatter
+// implementations consume it to produce the bytes written to the
+// underlying writer.
+type LogRecord struct {
+	Timestamp time.Time
+	Level     int
+	Module    string
+	Message   string
+	// Args holds the raw key/value pairs passed to the log call, e.g.
+	// []interface{}{"request_id", id, "mount", path}.
+	Args []interface{}
+}
+
+// LogFormatter renders a LogRecord into the bytes that will be written to
+// the log's underlying writer, including any trailing newline.
+type LogFormatter interface {
+	Format(*LogRecord) ([]byte, error)
+}
+
+// FormatterFactory constructs a new, independent LogFormatter instance.
+// Factories are called once per NewVaultLoggerWithFormatter call so that
+// stateful formatters (e.g. ones holding a bufio.Writer) aren't shared
+// across loggers created that way; loggers derived from one with
+// DeriveModuleLogger or With share its formatter instance.
+type FormatterFactory func() LogFormatter
+
+var (
+	formatterRegistryMu sync.RWMutex
+	formatterRegistry   = map[string]FormatterFactory{}
+)
+
+// RegisterFormatter makes a LogFormatter factory available under name for
+// later use with NewVaultLoggerWithFormatter. Downstream Vault plugins can
+// call this from an init() to ship CEE/GELF/ECS-shaped records without
+// patching this package. Registering under an existing name replaces it.
+func RegisterFormatter(name string, factory FormatterFactory) {
+	formatterRegistryMu.Lock()
+	defer formatterRegistryMu.Unlock()
+	formatterRegistry[name] = factory
+}
+
+// lookupFormatter returns a freshly constructed formatter registered under
+// name, if any.
+func lookupFormatter(name string) (LogFormatter, bool) {
+	formatterRegistryMu.RLock()
+	factory, ok := formatterRegistry[name]
+	formatterRegistryMu.RUnlock()
+	if !ok {
+		return nil, false
+	}
+	return factory(), true
+}
+
+func init() {
+	RegisterFormatter("text", func() LogFormatter { return &textFormatter{} })
+	RegisterFormatter("json", func() LogFormatter { return &jsonFormatter{} })
+	RegisterFormatter("logfmt", func() LogFormatter { return &logfmtFormatter{} })
+}
+
+// NewVaultLoggerWithFormatter creates a new logger with the specified level
+// and writer whose records are rendered by the LogFormatter registered
+// under name (see RegisterFormatter). Unknown names fall back to "text".
+//
+// "pretty" (and the LOGXI_FORMAT spellings "vault_pretty"/"vault-pretty"/
+// "vaultpretty") is handled specially rather than through the registry:
+// the pretty style needs w to decide whether to emit color, and
+// FormatterFactory has no writer to inspect.
+func NewVaultLoggerWithFormatter(w io.Writer, level int, name string) log.Logger {
+	logger := log.NewLogger(w, "vault")
+
+	switch name {
+	case "pretty", "vault_pretty", "vault-pretty", "vaultpretty":
+		return setLevelFormatter(logger, level, createVaultFormatterStyle(w, stylepretty))
+	}
+
+	impl, ok := lookupFormatter(name)
+	if !ok {
+		impl, _ = lookupFormatter("text")
+	}
+
+	formatter := &vaultFormatter{
+		Mutex:         &sync.Mutex{},
+		formatterImpl: impl,
+	}
+	return setLevelFormatter(logger, level, formatter)
+}
@@ -0,0 +1,88 @@
+package logformat
+
+import (
+	"bytes"
+	"testing"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+func TestLookupFormatterBuiltins(t *testing.T) {
+	tests := []struct {
+		name string
+		want LogFormatter
+	}{
+		{"text", &textFormatter{}},
+		{"json", &jsonFormatter{}},
+		{"logfmt", &logfmtFormatter{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := lookupFormatter(tt.name)
+			if !ok {
+				t.Fatalf("lookupFormatter(%q) not found", tt.name)
+			}
+			if gotType, wantType := typeName(got), typeName(tt.want); gotType != wantType {
+				t.Errorf("lookupFormatter(%q) = %s, want %s", tt.name, gotType, wantType)
+			}
+		})
+	}
+}
+
+func TestLookupFormatterUnknownName(t *testing.T) {
+	if _, ok := lookupFormatter("does-not-exist"); ok {
+		t.Error("lookupFormatter(unknown) reported ok = true")
+	}
+}
+
+// TestRegisterFormatterFactoryCalledPerLookup ensures RegisterFormatter's
+// factory produces a fresh instance on every lookup, as documented, so
+// stateful formatters aren't accidentally shared across loggers.
+func TestRegisterFormatterFactoryCalledPerLookup(t *testing.T) {
+	calls := 0
+	RegisterFormatter("test-counting", func() LogFormatter {
+		calls++
+		return &jsonFormatter{}
+	})
+
+	if _, ok := lookupFormatter("test-counting"); !ok {
+		t.Fatal("lookupFormatter(test-counting) not found")
+	}
+	if _, ok := lookupFormatter("test-counting"); !ok {
+		t.Fatal("lookupFormatter(test-counting) not found on second call")
+	}
+	if calls != 2 {
+		t.Errorf("factory called %d times, want 2 (once per lookup)", calls)
+	}
+}
+
+func TestNewVaultLoggerWithFormatterFallsBackToText(t *testing.T) {
+	logger := NewVaultLoggerWithFormatter(&bytes.Buffer{}, log.LevelInfo, "does-not-exist")
+	impl := logger.(*log.DefaultLogger).Formatter().(*vaultFormatter).formatterImpl
+	if _, ok := impl.(*textFormatter); !ok {
+		t.Errorf("formatterImpl = %T, want *textFormatter for an unknown name", impl)
+	}
+}
+
+func TestNewVaultLoggerWithFormatterUsesRegisteredFormatter(t *testing.T) {
+	RegisterFormatter("test-mycorp", func() LogFormatter { return &jsonFormatter{} })
+
+	logger := NewVaultLoggerWithFormatter(&bytes.Buffer{}, log.LevelInfo, "test-mycorp")
+	impl := logger.(*log.DefaultLogger).Formatter().(*vaultFormatter).formatterImpl
+	if _, ok := impl.(*jsonFormatter); !ok {
+		t.Errorf("formatterImpl = %T, want *jsonFormatter for a registered name", impl)
+	}
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case *textFormatter:
+		return "*textFormatter"
+	case *jsonFormatter:
+		return "*jsonFormatter"
+	case *logfmtFormatter:
+		return "*logfmtFormatter"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,115 @@
+package logformat
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+// Options configures NewSlogHandler.
+type Options struct {
+	// Level is the minimum logxi level (e.g. log.LevelInfo) the handler
+	// will emit. Defaults to log.LevelInfo if zero.
+	Level int
+}
+
+// NewSlogHandler returns a slog.Handler that writes through a Vault
+// formatter, letting Vault consumers use slog.Default() while retaining
+// the Vault text/JSON output shape.
+func NewSlogHandler(w io.Writer, opts Options) slog.Handler {
+	level := opts.Level
+	if level == 0 {
+		level = log.LevelInfo
+	}
+	return &SlogLogger{logger: NewVaultLoggerWithWriter(w, level)}
+}
+
+// SlogLogger adapts a Vault log.Logger to satisfy slog.Handler. attrs
+// holds the key/value pairs bound by prior WithAttrs calls, which Handle
+// prepends to each record's own attrs.
+type SlogLogger struct {
+	logger log.Logger
+	attrs  []slog.Attr
+}
+
+// levelFromSlog maps a slog.Level onto the closest logxi level, spreading
+// custom/out-of-range levels onto Trace (very verbose) and Critical (more
+// severe than Error).
+func levelFromSlog(l slog.Level) int {
+	switch {
+	case l < slog.LevelDebug:
+		return log.LevelTrace
+	case l < slog.LevelInfo:
+		return log.LevelDebug
+	case l < slog.LevelWarn:
+		return log.LevelInfo
+	case l < slog.LevelError:
+		return log.LevelWarn
+	case l == slog.LevelError:
+		return log.LevelError
+	default:
+		return log.LevelCritical
+	}
+}
+
+func (s *SlogLogger) Enabled(_ context.Context, level slog.Level) bool {
+	switch levelFromSlog(level) {
+	case log.LevelTrace:
+		return s.logger.IsTrace()
+	case log.LevelDebug:
+		return s.logger.IsDebug()
+	case log.LevelInfo:
+		return s.logger.IsInfo()
+	case log.LevelWarn:
+		return s.logger.IsWarn()
+	default:
+		// log.Logger has no IsError/IsCritical: logxi's own design is
+		// that Error and above should always be logged, so Enabled must
+		// not gate them behind a level check.
+		return true
+	}
+}
+
+func (s *SlogLogger) Handle(_ context.Context, r slog.Record) error {
+	args := make([]interface{}, 0, len(s.attrs)*2+r.NumAttrs()*2)
+	for _, a := range s.attrs {
+		args = append(args, a.Key, a.Value.Any())
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		args = append(args, a.Key, a.Value.Any())
+		return true
+	})
+
+	switch levelFromSlog(r.Level) {
+	case log.LevelTrace:
+		s.logger.Trace(r.Message, args...)
+	case log.LevelDebug:
+		s.logger.Debug(r.Message, args...)
+	case log.LevelInfo:
+		s.logger.Info(r.Message, args...)
+	case log.LevelWarn:
+		s.logger.Warn(r.Message, args...)
+	default:
+		// logxi's Fatal calls os.Exit, which a slog.Handler must never
+		// do on the caller's behalf, so Error and Critical both land
+		// here.
+		s.logger.Error(r.Message, args...)
+	}
+	return nil
+}
+
+func (s *SlogLogger) WithAttrs(attrs []slog.Attr) slog.Handler {
+	merged := make([]slog.Attr, 0, len(s.attrs)+len(attrs))
+	merged = append(merged, s.attrs...)
+	merged = append(merged, attrs...)
+	return &SlogLogger{logger: s.logger, attrs: merged}
+}
+
+func (s *SlogLogger) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return s
+	}
+	return &SlogLogger{logger: DeriveModuleLogger(s.logger, name), attrs: s.attrs}
+}
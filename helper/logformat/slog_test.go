@@ -0,0 +1,96 @@
+package logformat
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+func TestLevelFromSlog(t *testing.T) {
+	tests := []struct {
+		level slog.Level
+		want  int
+	}{
+		{slog.LevelDebug - 4, log.LevelTrace},
+		{slog.LevelDebug, log.LevelDebug},
+		{slog.LevelInfo, log.LevelInfo},
+		{slog.LevelWarn, log.LevelWarn},
+		{slog.LevelError, log.LevelError},
+		{slog.LevelError + 4, log.LevelCritical},
+	}
+	for _, tt := range tests {
+		if got := levelFromSlog(tt.level); got != tt.want {
+			t.Errorf("levelFromSlog(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestSlogLoggerEnabled(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(&buf, Options{Level: log.LevelWarn})
+
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Info should not be enabled when the logger level is Warn")
+	}
+	if !h.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("Warn should be enabled when the logger level is Warn")
+	}
+	if !h.Enabled(context.Background(), slog.LevelError) {
+		t.Error("Error should always be enabled regardless of the configured level")
+	}
+}
+
+func TestSlogLoggerHandleWritesThroughVaultFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(&buf, Options{Level: log.LevelInfo})
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+	rec.AddAttrs(slog.String("key", "value"))
+	if err := h.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "hello") || !strings.Contains(out, "key=value") {
+		t.Errorf("unexpected output: %q", out)
+	}
+}
+
+func TestSlogLoggerWithAttrsPreBindsArgs(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(&buf, Options{Level: log.LevelInfo})
+	h2 := h.WithAttrs([]slog.Attr{slog.String("bound", "yes")})
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+	if err := h2.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "bound=yes") {
+		t.Errorf("expected bound attr in output, got %q", buf.String())
+	}
+}
+
+func TestSlogLoggerWithGroupComposesModule(t *testing.T) {
+	var buf bytes.Buffer
+	h := NewSlogHandler(&buf, Options{Level: log.LevelInfo})
+	h2 := h.WithGroup("mount")
+
+	rec := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+	if err := h2.Handle(context.Background(), rec); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "(mount)") {
+		t.Errorf("expected module (mount) in output, got %q", buf.String())
+	}
+
+	if h3 := h2.WithGroup(""); h3 != h2 {
+		t.Error("WithGroup(\"\") should return the receiver unchanged")
+	}
+}
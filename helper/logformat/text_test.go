@@ -0,0 +1,39 @@
+package logformat
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestTextFormatterIncludeLocationResolvesOwnPackageCaller is a
+// regression test for caller() mistaking its own package's test files
+// for formatter-internal frames: a path-substring check matches any file
+// under helper/logformat, including this one, and used to keep walking
+// past the real call site into the testing package's runner frame.
+func TestTextFormatterIncludeLocationResolvesOwnPackageCaller(t *testing.T) {
+	tf := &textFormatter{includeLocation: true}
+	rec := &LogRecord{Message: "hi"}
+
+	b, err := tf.Format(rec)
+	if err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	out := string(b)
+
+	if !strings.Contains(out, "caller=") {
+		t.Fatalf("expected a caller= field in output, got %q", out)
+	}
+	if !strings.Contains(out, "text_test.go") {
+		t.Errorf("caller location should point at this test file, got %q", out)
+	}
+	if strings.Contains(out, "testing.go") {
+		t.Errorf("caller location resolved to the test runner instead of the call site, got %q", out)
+	}
+}
+
+func TestCallerSkipsFormatterInternals(t *testing.T) {
+	loc := caller()
+	if !strings.Contains(loc, "text_test.go") {
+		t.Errorf("caller() = %q, want a location in this test file", loc)
+	}
+}
@@ -1,34 +1,49 @@
 package logformat
 
 import (
-	"encoding/json"
-	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
+	isatty "github.com/mattn/go-isatty"
+
 	log "github.com/jefferai/logxi/v1"
 )
 
 const (
 	styledefault = iota
 	stylejson
+	stylepretty
 )
 
 // NewVaultLogger creates a new logger with the specified level and a Vault
 // formatter
 func NewVaultLogger(level int) log.Logger {
 	logger := log.New("vault")
-	return setLevelFormatter(logger, level, createVaultFormatter())
+	// log.New writes to logxi's colorable stdout wrapper, not stderr, so
+	// the TTY/NO_COLOR detection in createVaultFormatter must be made
+	// against os.Stdout too; otherwise redirecting stdout to a file while
+	// stderr stays a TTY would leak raw ANSI escapes into the file.
+	return setLevelFormatter(logger, level, createVaultFormatter(os.Stdout))
 }
 
 // NewVaultLoggerWithWriter creates a new logger with the specified level and
 // writer and a Vault formatter
 func NewVaultLoggerWithWriter(w io.Writer, level int) log.Logger {
 	logger := log.NewLogger(w, "vault")
-	return setLevelFormatter(logger, level, createVaultFormatter())
+	return setLevelFormatter(logger, level, createVaultFormatter(w))
+}
+
+// NewVaultLoggerPretty creates a new logger with the specified level and
+// writer, forcing the pretty (colorized, TTY-aware) console style
+// regardless of LOGXI_FORMAT. This is the programmatic equivalent of
+// setting LOGXI_FORMAT=vault_pretty, for callers that want to opt in from
+// code rather than the environment.
+func NewVaultLoggerPretty(w io.Writer, level int) log.Logger {
+	logger := log.NewLogger(w, "vault")
+	return setLevelFormatter(logger, level, createVaultFormatterStyle(w, stylepretty))
 }
 
 // Sets the level and formatter on the log, which must be a DefaultLogger
@@ -48,8 +63,9 @@ func DeriveModuleLogger(logger log.Logger, module string) log.Logger {
 	defLogger := logger.(*log.DefaultLogger)
 	formatter := defLogger.Formatter().(*vaultFormatter)
 	newFormatter := &vaultFormatter{
-		Mutex: formatter.Mutex,
-		style: formatter.style,
+		Mutex:         formatter.Mutex,
+		formatterImpl: formatter.formatterImpl,
+		implied:       formatter.implied,
 	}
 	switch {
 	case module == "":
@@ -59,140 +75,139 @@ func DeriveModuleLogger(logger log.Logger, module string) log.Logger {
 	case formatter.module == "":
 		newFormatter.module = module
 	default:
-		newFormatter.module = fmt.Sprintf("%s/%s", formatter.module, module)
+		newFormatter.module = formatter.module + "/" + module
 	}
 
 	newLogger := log.NewLogger(defLogger.Writer(), "vault")
 	return setLevelFormatter(newLogger, defLogger.Level(), newFormatter)
 }
 
-// Creates a formatter, checking env vars for the style
-func createVaultFormatter() log.Formatter {
-	ret := &vaultFormatter{
-		Mutex: &sync.Mutex{},
+// With returns a derived logger (parallel to DeriveModuleLogger) whose
+// formatter prepends args, as "implied" key/value pairs, ahead of the
+// args passed to each subsequent call. Implied pairs accumulate across
+// chained With calls, and in JSON mode a per-call arg with the same key
+// overrides the implied one rather than appearing twice.
+func With(logger log.Logger, args ...interface{}) log.Logger {
+	defLogger := logger.(*log.DefaultLogger)
+	formatter := defLogger.Formatter().(*vaultFormatter)
+
+	implied := make([]interface{}, 0, len(formatter.implied)+len(args))
+	implied = append(implied, formatter.implied...)
+	implied = append(implied, args...)
+
+	newFormatter := &vaultFormatter{
+		Mutex:         formatter.Mutex,
+		module:        formatter.module,
+		formatterImpl: formatter.formatterImpl,
+		implied:       implied,
 	}
+
+	newLogger := log.NewLogger(defLogger.Writer(), "vault")
+	return setLevelFormatter(newLogger, defLogger.Level(), newFormatter)
+}
+
+// Creates a formatter, checking env vars for the style
+func createVaultFormatter(w io.Writer) log.Formatter {
+	return createVaultFormatterStyle(w, styleFromEnv())
+}
+
+// styleFromEnv reads LOGXI_FORMAT to pick a style, defaulting to
+// styledefault when unset or unrecognized.
+func styleFromEnv() int {
 	switch os.Getenv("LOGXI_FORMAT") {
 	case "vault_json", "vault-json", "vaultjson":
-		ret.style = stylejson
+		return stylejson
+	case "vault_pretty", "vault-pretty", "vaultpretty":
+		return stylepretty
 	default:
-		ret.style = styledefault
+		return styledefault
 	}
-	return ret
 }
 
-// Thread safe formatter
-type vaultFormatter struct {
-	*sync.Mutex
-	style  int
-	module string
-}
+// createVaultFormatterStyle builds a formatter for an explicit style,
+// letting callers (like NewVaultLoggerPretty) bypass LOGXI_FORMAT
+// entirely while still sharing the TTY/NO_COLOR color detection and
+// IncludeLocation handling.
+func createVaultFormatterStyle(w io.Writer, style int) log.Formatter {
+	ret := &vaultFormatter{
+		Mutex: &sync.Mutex{},
+	}
 
-func (v *vaultFormatter) Format(writer io.Writer, level int, msg string, args []interface{}) {
-	v.Lock()
-	defer v.Unlock()
-	switch v.style {
+	useColor := style == stylepretty && shouldUseColor(w)
+	includeLocation := os.Getenv("LOGXI_INCLUDE_LOCATION") != ""
+
+	switch style {
 	case stylejson:
-		v.formatJSON(writer, level, msg, args)
+		ret.formatterImpl = &jsonFormatter{}
 	default:
-		v.formatDefault(writer, level, msg, args)
+		ret.formatterImpl = &textFormatter{useColor: useColor, includeLocation: includeLocation}
 	}
+
+	return ret
 }
 
-func (v *vaultFormatter) formatDefault(writer io.Writer, level int, msg string, args []interface{}) {
-	// Write a trailing newline
-	defer writer.Write([]byte("\n"))
-
-	writer.Write([]byte(time.Now().Local().Format("2006/01/02 15:04:05.000000")))
-
-	switch level {
-	case log.LevelCritical:
-		writer.Write([]byte(" [CRT] "))
-	case log.LevelError:
-		writer.Write([]byte(" [ERR] "))
-	case log.LevelWarn:
-		writer.Write([]byte(" [WRN] "))
-	case log.LevelInfo:
-		writer.Write([]byte(" [INF] "))
-	case log.LevelDebug:
-		writer.Write([]byte(" [DBG] "))
-	case log.LevelTrace:
-		writer.Write([]byte(" [TRC] "))
-	default:
-		writer.Write([]byte(" [ALL] "))
+// shouldUseColor decides whether ANSI colors should be written to w. It
+// honors NO_COLOR (https://no-color.org/), an explicit LOGXI_FORCE_COLOR
+// override, and falls back to TTY detection so redirecting output to a
+// file still produces plain text.
+func shouldUseColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
 	}
-
-	if v.module != "" {
-		writer.Write([]byte(fmt.Sprintf("(%s) ", v.module)))
+	if os.Getenv("LOGXI_FORCE_COLOR") != "" {
+		return true
 	}
-
-	writer.Write([]byte(msg))
-
-	if args != nil && len(args) > 0 {
-		if len(args)%2 != 0 {
-			args = append(args, "[unknown!]")
-		}
-
-		writer.Write([]byte(":"))
-
-		for i := 0; i < len(args); i = i + 2 {
-			var quote string
-			switch args[i+1].(type) {
-			case string:
-				if strings.ContainsRune(args[i+1].(string), ' ') {
-					quote = `"`
-				}
-			}
-			writer.Write([]byte(fmt.Sprintf(" %s=%s%v%s", args[i], quote, args[i+1], quote)))
-		}
+	type fder interface {
+		Fd() uintptr
+	}
+	f, ok := w.(fder)
+	if !ok {
+		return false
 	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
 }
 
-func (v *vaultFormatter) formatJSON(writer io.Writer, level int, msg string, args []interface{}) {
-	vals := map[string]interface{}{
-		"@message":   msg,
-		"@timestamp": time.Now().Format("2006-01-02T15:04:05.000000Z07:00"),
-	}
+// Thread safe formatter. It builds a LogRecord from each call and delegates
+// the actual rendering to formatterImpl, so alternate LogFormatter
+// implementations can be swapped in without touching the logxi glue here.
+type vaultFormatter struct {
+	*sync.Mutex
+	module        string
+	formatterImpl LogFormatter
+	// implied holds key/value pairs set via With that are merged into
+	// every record ahead of the pairs passed to the call itself.
+	implied []interface{}
+}
 
-	var levelStr string
-	switch level {
-	case log.LevelCritical:
-		levelStr = "critical"
-	case log.LevelError:
-		levelStr = "error"
-	case log.LevelWarn:
-		levelStr = "warn"
-	case log.LevelInfo:
-		levelStr = "info"
-	case log.LevelDebug:
-		levelStr = "debug"
-	case log.LevelTrace:
-		levelStr = "trace"
-	default:
-		levelStr = "all"
+func (v *vaultFormatter) Format(writer io.Writer, level int, msg string, args []interface{}) {
+	// v.module and v.implied are set once at construction and never
+	// mutated afterward, so it's safe to read them here without holding
+	// the lock: the expensive formatting step runs off the hot path, and
+	// the lock is only held long enough to serialize the actual write to
+	// the shared writer.
+	if len(v.implied) > 0 {
+		merged := make([]interface{}, 0, len(v.implied)+len(args))
+		merged = append(merged, v.implied...)
+		merged = append(merged, args...)
+		args = merged
 	}
 
-	vals["@level"] = levelStr
-
-	if v.module != "" {
-		vals["@module"] = v.module
+	record := &LogRecord{
+		Timestamp: time.Now(),
+		Level:     level,
+		Module:    v.module,
+		Message:   msg,
+		Args:      args,
 	}
 
-	if args != nil && len(args) > 0 {
-
-		if len(args)%2 != 0 {
-			args = append(args, "[unknown!]")
-		}
-
-		for i := 0; i < len(args); i = i + 2 {
-			if _, ok := args[i].(string); !ok {
-				// As this is the logging function not much we can do here
-				// without injecting into logs...
-				continue
-			}
-			vals[args[i].(string)] = args[i+1]
-		}
+	b, err := v.formatterImpl.Format(record)
+	if err != nil {
+		// Formatting must never be fatal to the caller; surface the
+		// failure inline rather than dropping the record entirely.
+		b = []byte("logformat: error formatting record: " + err.Error() + "\n")
 	}
 
-	enc := json.NewEncoder(writer)
-	enc.Encode(vals)
-}
\ No newline at end of file
+	v.Lock()
+	defer v.Unlock()
+	writer.Write(b)
+}
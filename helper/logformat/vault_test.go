@@ -0,0 +1,60 @@
+package logformat
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestShouldUseColor(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe: %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	t.Run("NO_COLOR wins even over LOGXI_FORCE_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		t.Setenv("LOGXI_FORCE_COLOR", "1")
+		if shouldUseColor(w) {
+			t.Error("shouldUseColor = true, want false when NO_COLOR is set")
+		}
+	})
+
+	t.Run("LOGXI_FORCE_COLOR forces color for a non-tty writer", func(t *testing.T) {
+		t.Setenv("LOGXI_FORCE_COLOR", "1")
+		if !shouldUseColor(w) {
+			t.Error("shouldUseColor = false, want true when LOGXI_FORCE_COLOR is set")
+		}
+	})
+
+	t.Run("a writer with no Fd falls back to false", func(t *testing.T) {
+		if shouldUseColor(&bytes.Buffer{}) {
+			t.Error("shouldUseColor = true, want false for a writer that isn't a file descriptor")
+		}
+	})
+
+	t.Run("a non-tty file descriptor is not colorized", func(t *testing.T) {
+		// A pipe has an Fd() but isatty.IsTerminal reports false for it,
+		// exercising the same path a redirected-to-file run would take.
+		if shouldUseColor(w) {
+			t.Error("shouldUseColor = true, want false for a non-terminal file descriptor")
+		}
+	})
+}
+
+func TestCreateVaultFormatterStyleDefaultsNeverColorize(t *testing.T) {
+	// Even with LOGXI_FORCE_COLOR set, which makes shouldUseColor report
+	// true for any writer, styledefault must not request color — only
+	// stylepretty does.
+	t.Setenv("LOGXI_FORCE_COLOR", "1")
+	f := createVaultFormatterStyle(&bytes.Buffer{}, styledefault)
+	tf, ok := f.(*vaultFormatter).formatterImpl.(*textFormatter)
+	if !ok {
+		t.Fatalf("formatterImpl = %T, want *textFormatter", f.(*vaultFormatter).formatterImpl)
+	}
+	if tf.useColor {
+		t.Error("styledefault formatter has useColor = true, want false")
+	}
+}
@@ -0,0 +1,64 @@
+package logformat
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	log "github.com/jefferai/logxi/v1"
+)
+
+func TestWithMergesImpliedArgsTextMode(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewVaultLoggerWithFormatter(&buf, log.LevelInfo, "text")
+	derived := With(base, "request_id", "abc123")
+
+	derived.Info("hello", "extra", "value")
+
+	out := buf.String()
+	if !strings.Contains(out, "request_id=abc123") {
+		t.Errorf("expected implied request_id in output, got %q", out)
+	}
+	if !strings.Contains(out, "extra=value") {
+		t.Errorf("expected per-call arg in output, got %q", out)
+	}
+	if strings.Index(out, "request_id") > strings.Index(out, "extra") {
+		t.Errorf("expected implied args to precede per-call args, got %q", out)
+	}
+}
+
+func TestWithChainsAccumulateImpliedArgs(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewVaultLoggerWithFormatter(&buf, log.LevelInfo, "json")
+	derived := With(With(base, "a", "1"), "b", "2")
+
+	derived.Info("hello")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v (%s)", err, buf.Bytes())
+	}
+	if decoded["a"] != "1" || decoded["b"] != "2" {
+		t.Errorf("expected both chained implied args present, got %v", decoded)
+	}
+}
+
+// TestWithJSONModePerCallArgOverridesImplied exercises the collision rule
+// called out in the request: a per-call arg with the same key as an
+// implied one must win, not the other way around and not both appearing.
+func TestWithJSONModePerCallArgOverridesImplied(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewVaultLoggerWithFormatter(&buf, log.LevelInfo, "json")
+	derived := With(base, "request_id", "implied-value")
+
+	derived.Info("hello", "request_id", "override-value")
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v (%s)", err, buf.Bytes())
+	}
+	if decoded["request_id"] != "override-value" {
+		t.Errorf("request_id = %v, want override-value (the per-call arg should win)", decoded["request_id"])
+	}
+}